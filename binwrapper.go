@@ -6,14 +6,11 @@ import (
 	"path/filepath"
 	"os"
 	"errors"
-	"os/exec"
-	"net/url"
-	"strings"
 	"net/http"
 	"io"
-	"github.com/mholt/archiver"
 	"fmt"
-	"io/ioutil"
+	"context"
+	"time"
 )
 
 type Src struct {
@@ -21,6 +18,15 @@ type Src struct {
 	os       string
 	arch     string
 	execPath string
+
+	checksumAlgo string
+	checksumHex  string
+	sigURL       string
+	sigPubKey    string
+
+	version string
+
+	archAliases map[string]string
 }
 
 type BinWrapper struct {
@@ -38,6 +44,21 @@ type BinWrapper struct {
 
 	//Contains arguments were added with Arg method
 	Args     []string
+
+	strictVerify bool
+
+	versionConstraint string
+	resolvedVersion   string
+
+	stdout   io.Writer
+	stderr   io.Writer
+	stdin    io.Reader
+	timeout  time.Duration
+	exitCode int
+
+	httpClient *http.Client
+	userAgent  string
+	progress   ProgressFunc
 }
 
 //Creates new Src instance
@@ -71,7 +92,7 @@ func (s *Src) ExecPath(value string) *Src {
 
 //Creates ready to use BinWrapper instance
 func NewBinWrapper() *BinWrapper {
-	return &BinWrapper{}
+	return &BinWrapper{exitCode: -1}
 }
 
 //Adds a source to download
@@ -119,6 +140,10 @@ func (b *BinWrapper) Path() string {
 		b.ExecPath(src.execPath)
 	}
 
+	if b.resolvedVersion != "" {
+		return filepath.Join(b.dest, b.versionDir(b.resolvedVersion), b.execPath)
+	}
+
 	if b.dest == "." {
 		return b.dest + string(filepath.Separator) + b.execPath
 	} else {
@@ -137,34 +162,30 @@ func (b *BinWrapper) Reset() *BinWrapper {
 //Runs the binary with provided arg list.
 //Arg list is appended to args set through Arg method
 func (b *BinWrapper) Run(arg ...string) error {
+	return b.RunContext(context.Background(), arg...)
+}
 
-	if b.src != nil && len(b.src) > 0 {
-		err := b.findExisting()
+func (b *BinWrapper) findExisting() error {
+	if b.versionConstraint != "" {
+		if src := osFilterObj(b.src); src != nil && src.execPath != "" {
+			b.ExecPath(src.execPath)
+		}
+
+		version, err := b.bestInstalledVersion()
 
 		if err != nil {
 			return err
 		}
-	}
-
-	arg = append(b.Args, arg...)
-
-	cmd := exec.Command(b.Path(), arg...)
-	stdout, _ := cmd.StdoutPipe()
-	stderr, _ := cmd.StderrPipe()
 
-	err := cmd.Start()
+		if version != "" {
+			b.resolvedVersion = version
+			return nil
+		}
 
-	if err != nil {
-		return err
+		fmt.Printf("no installed version of %s satisfies %q. Downloading...\n", b.binaryName(), b.versionConstraint)
+		return b.download()
 	}
 
-	cmd.CombinedOutput()
-	b.StdOut, _ = ioutil.ReadAll(stdout)
-	b.StdErr, _ = ioutil.ReadAll(stderr)
-	return cmd.Wait()
-}
-
-func (b *BinWrapper) findExisting() error {
 	_, err := os.Stat(b.Path())
 
 	if os.IsNotExist(err) {
@@ -178,13 +199,23 @@ func (b *BinWrapper) findExisting() error {
 }
 
 func (b *BinWrapper) download() error {
-	src := osFilterObj(b.src)
+	src := b.matchingSrc()
 
 	if src == nil {
 		return errors.New("No binary found matching your system. It's probably not supported.")
 	}
 
-	file, err := b.downloadFile(src.url)
+	if src.version != "" {
+		b.resolvedVersion = src.version
+	}
+
+	file, err := b.downloadFile(src.url, src)
+
+	if err != nil {
+		return err
+	}
+
+	err = b.verify(src, file)
 
 	if err != nil {
 		return err
@@ -206,128 +237,61 @@ func (b *BinWrapper) download() error {
 }
 
 func (b *BinWrapper) extractFile(file string) error {
-	var arc archiver.Archiver
-
-	for _, v := range archiver.SupportedFormats {
-		if v.Match(file) {
-			arc = v
-			break
-		}
-	}
-
-	if arc == nil {
-		fmt.Printf("%s not an archive or have unsupported archive format", file)
-		return nil
-	}
-
-	err := arc.Open(file, b.dest)
+	header, err := readFileHeader(file)
 
 	if err != nil {
 		return err
 	}
 
-	os.Remove(file)
-
-	if b.strip == 0 {
-		return nil
-	} else {
-		return b.stripDir()
-	}
-}
-
-func (b *BinWrapper) stripDir() error {
-	dir := b.dest
-
-	var dirsToRemove []string
+	var ext Extractor
 
-	for i := 0; i < b.strip; i++ {
-		files, err := ioutil.ReadDir(dir)
-
-		if err != nil {
-			return err
+	for _, e := range extractors {
+		if e.Match(file, header) {
+			ext = e
+			break
 		}
+	}
 
-		for _, v := range files {
-			if v.IsDir() {
-
-				if dir != b.dest {
-					dirsToRemove = append(dirsToRemove, dir)
-				}
-
-				dir = filepath.Join(dir, v.Name())
-				break
-			}
-		}
+	if ext == nil {
+		fmt.Printf("%s not an archive or have unsupported archive format", file)
+		return nil
 	}
 
-	files, err := ioutil.ReadDir(dir)
+	err = ext.Extract(file, b.extractDest(), b.strip)
 
 	if err != nil {
 		return err
 	}
 
-	for _, v := range files {
-		err := os.Rename(filepath.Join(dir, v.Name()), filepath.Join(b.dest, v.Name()))
-
-		if err != nil {
-			return err
-		}
-	}
-
-	for _, v := range dirsToRemove {
-		os.RemoveAll(v)
-	}
-
-	return nil
+	return os.Remove(file)
 }
 
-func (b *BinWrapper) downloadFile(value string) (string, error) {
-
-	if b.dest == "" {
-		b.dest = "."
-	}
-
-	err := os.MkdirAll(b.dest, 0755)
-
-	if err != nil {
-		return "", err
-	}
-
-	fileURL, err := url.Parse(value)
+func readFileHeader(file string) ([]byte, error) {
+	f, err := os.Open(file)
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	path := fileURL.Path
+	defer f.Close()
 
-	segments := strings.Split(path, "/")
-	fileName := segments[len(segments)-1]
-	fileName = filepath.Join(b.dest, fileName)
-	file, err := os.OpenFile(fileName, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755)
+	buf := make([]byte, 512)
+	n, err := f.Read(buf)
 
-	if err != nil {
-		return "", err
-	}
-
-	defer file.Close()
-
-	check := http.Client{
-		CheckRedirect: func(r *http.Request, via []*http.Request) error {
-			r.URL.Opaque = r.URL.Path
-			return nil
-		},
+	if err != nil && err != io.EOF {
+		return nil, err
 	}
 
-	resp, err := check.Get(value)
+	return buf[:n], nil
+}
 
-	if err != nil {
-		return "", err
+//extractDest is where archives are unpacked to: Dest, or Dest/<binary>-<version>
+//when a Version constraint has been resolved.
+func (b *BinWrapper) extractDest() string {
+	if b.resolvedVersion == "" {
+		return b.dest
 	}
 
-	defer resp.Body.Close()
-
-	_, err = io.Copy(file, resp.Body)
-
-	return fileName, err
+	return filepath.Join(b.dest, b.versionDir(b.resolvedVersion))
 }
+
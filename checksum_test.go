@@ -0,0 +1,195 @@
+package binwrapper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+func writeTempFile(t *testing.T, content []byte) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "payload")
+
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestVerifyChecksumAcceptsMatchingDigest(t *testing.T) {
+	content := []byte("hello world")
+	sum := sha256.Sum256(content)
+	file := writeTempFile(t, content)
+
+	b := NewBinWrapper()
+	src := NewSrc().Checksum("sha256", hex.EncodeToString(sum[:]))
+
+	if err := b.verify(src, file); err != nil {
+		t.Fatalf("verify() with a matching digest = %v, want nil", err)
+	}
+}
+
+func TestVerifyChecksumRejectsMismatch(t *testing.T) {
+	file := writeTempFile(t, []byte("hello world"))
+
+	b := NewBinWrapper()
+	src := NewSrc().Checksum("sha256", "0000000000000000000000000000000000000000000000000000000000000000")
+
+	err := b.verify(src, file)
+
+	mismatch, ok := err.(*ChecksumMismatchError)
+
+	if !ok {
+		t.Fatalf("verify() error = %v (%T), want *ChecksumMismatchError", err, err)
+	}
+
+	if mismatch.Expected != src.checksumHex {
+		t.Errorf("Expected = %q, want %q", mismatch.Expected, src.checksumHex)
+	}
+
+	want := sha256.Sum256([]byte("hello world"))
+
+	if mismatch.Actual != hex.EncodeToString(want[:]) {
+		t.Errorf("Actual = %q, want %q", mismatch.Actual, hex.EncodeToString(want[:]))
+	}
+}
+
+func TestVerifyStrictFailsClosedWithoutChecksumOrSignature(t *testing.T) {
+	file := writeTempFile(t, []byte("hello world"))
+
+	b := NewBinWrapper().StrictVerify(true)
+	src := NewSrc()
+
+	if err := b.verify(src, file); err == nil {
+		t.Fatal("verify() with StrictVerify and no checksum/signature = nil, want an error")
+	}
+}
+
+func TestVerifyStrictPassesWhenChecksumPresent(t *testing.T) {
+	content := []byte("hello world")
+	sum := sha256.Sum256(content)
+	file := writeTempFile(t, content)
+
+	b := NewBinWrapper().StrictVerify(true)
+	src := NewSrc().Checksum("sha256", hex.EncodeToString(sum[:]))
+
+	if err := b.verify(src, file); err != nil {
+		t.Fatalf("verify() with StrictVerify and a matching checksum = %v, want nil", err)
+	}
+}
+
+//newTestKeyPair returns an armored public key and the entity that can sign
+//for it, for exercising verifySignature without a real release key.
+func newTestKeyPair(t *testing.T) (*openpgp.Entity, string) {
+	t.Helper()
+
+	entity, err := openpgp.NewEntity("binwrapper test", "", "test@example.com", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return entity, buf.String()
+}
+
+func TestVerifySignatureAcceptsValidSignature(t *testing.T) {
+	content := []byte("hello world")
+	file := writeTempFile(t, content)
+
+	entity, pubKey := newTestKeyPair(t)
+
+	var sig bytes.Buffer
+
+	if err := openpgp.DetachSign(&sig, entity, bytes.NewReader(content), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(sig.Bytes())
+	}))
+
+	defer server.Close()
+
+	b := NewBinWrapper()
+	src := NewSrc().Signature(server.URL, pubKey)
+
+	if err := b.verify(src, file); err != nil {
+		t.Fatalf("verify() with a valid signature = %v, want nil", err)
+	}
+}
+
+func TestVerifySignatureRejectsTamperedContent(t *testing.T) {
+	entity, pubKey := newTestKeyPair(t)
+
+	var sig bytes.Buffer
+
+	if err := openpgp.DetachSign(&sig, entity, bytes.NewReader([]byte("hello world")), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	file := writeTempFile(t, []byte("tampered content"))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(sig.Bytes())
+	}))
+
+	defer server.Close()
+
+	b := NewBinWrapper()
+	src := NewSrc().Signature(server.URL, pubKey)
+
+	if err := b.verify(src, file); err == nil {
+		t.Fatal("verify() with a signature over different content = nil, want an error")
+	}
+}
+
+func TestNewSignatureRequestSetsUserAgent(t *testing.T) {
+	req, err := newSignatureRequest("https://example.com/release.sig", "binwrapper-test/1.0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := req.Header.Get("User-Agent"); got != "binwrapper-test/1.0" {
+		t.Fatalf("User-Agent header = %q, want %q", got, "binwrapper-test/1.0")
+	}
+}
+
+func TestNewSignatureRequestLeavesUserAgentUnsetWhenEmpty(t *testing.T) {
+	req, err := newSignatureRequest("https://example.com/release.sig", "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := req.Header.Get("User-Agent"); got != "" {
+		t.Fatalf("User-Agent header = %q, want empty", got)
+	}
+}
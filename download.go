@@ -0,0 +1,224 @@
+package binwrapper
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+//ProgressFunc is invoked periodically while a download runs, with the number
+//of bytes downloaded so far and the total reported by the server (0 if the
+//server didn't send a Content-Length).
+type ProgressFunc func(downloaded, total int64)
+
+//maxDownloadAttempts bounds the exponential-backoff retry loop for
+//transient/5xx failures.
+const maxDownloadAttempts = 5
+
+//retryableError marks a download failure as transient, worth retrying.
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+func isRetryable(err error) bool {
+	_, ok := err.(*retryableError)
+	return ok
+}
+
+//HTTPClient sets the client used for downloads, e.g. to inject custom TLS
+//config, a proxy, or auth headers needed for private GitHub release assets.
+func (b *BinWrapper) HTTPClient(client *http.Client) *BinWrapper {
+	b.httpClient = client
+	return b
+}
+
+//UserAgent sets the User-Agent header sent with download requests. Many
+//hosts (dl.google.com, GitHub) treat requests with no UA poorly.
+func (b *BinWrapper) UserAgent(value string) *BinWrapper {
+	b.userAgent = value
+	return b
+}
+
+//Progress registers a callback invoked periodically during downloads with
+//the bytes downloaded so far and the total size, if known.
+func (b *BinWrapper) Progress(fn ProgressFunc) *BinWrapper {
+	b.progress = fn
+	return b
+}
+
+func (b *BinWrapper) httpClientOrDefault() *http.Client {
+	if b.httpClient != nil {
+		return b.httpClient
+	}
+
+	return &http.Client{
+		CheckRedirect: func(r *http.Request, via []*http.Request) error {
+			r.URL.Opaque = r.URL.Path
+			return nil
+		},
+	}
+}
+
+//downloadFile downloads value into Dest, resuming a previous partial
+//download via HTTP Range and retrying transient/5xx failures with
+//exponential backoff.
+func (b *BinWrapper) downloadFile(value string, src *Src) (string, error) {
+	if b.dest == "" {
+		b.dest = "."
+	}
+
+	if err := os.MkdirAll(b.dest, 0755); err != nil {
+		return "", err
+	}
+
+	fileURL, err := url.Parse(value)
+
+	if err != nil {
+		return "", err
+	}
+
+	segments := strings.Split(fileURL.Path, "/")
+	fileName := filepath.Join(b.dest, segments[len(segments)-1])
+	partName := fileName + ".part"
+
+	var lastErr error
+
+	for attempt := 0; attempt < maxDownloadAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(downloadBackoff(attempt))
+		}
+
+		lastErr = b.downloadAttempt(value, partName)
+
+		if lastErr == nil || !isRetryable(lastErr) {
+			break
+		}
+
+		fmt.Printf("download of %s failed (%v), retrying...\n", value, lastErr)
+	}
+
+	if lastErr != nil {
+		return "", lastErr
+	}
+
+	if err := os.Rename(partName, fileName); err != nil {
+		return "", err
+	}
+
+	return fileName, nil
+}
+
+func downloadBackoff(attempt int) time.Duration {
+	return (1 << uint(attempt)) * 250 * time.Millisecond
+}
+
+//downloadAttempt performs a single download pass, resuming from the size of
+//an existing partName if the server honors the Range header.
+func (b *BinWrapper) downloadAttempt(value, partName string) error {
+	offset := int64(0)
+
+	if info, err := os.Stat(partName); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequest("GET", value, nil)
+
+	if err != nil {
+		return err
+	}
+
+	if b.userAgent != "" {
+		req.Header.Set("User-Agent", b.userAgent)
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := b.httpClientOrDefault().Do(req)
+
+	if err != nil {
+		return &retryableError{err}
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return &retryableError{fmt.Errorf("server returned %s", resp.Status)}
+	}
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("download failed: %s", resp.Status)
+	}
+
+	flags := os.O_RDWR | os.O_CREATE
+
+	if offset > 0 && resp.StatusCode == http.StatusPartialContent {
+		flags |= os.O_APPEND
+	} else {
+		flags |= os.O_TRUNC
+		offset = 0
+	}
+
+	file, err := os.OpenFile(partName, flags, 0755)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	var w io.Writer = file
+
+	if b.progress != nil {
+		total := normalizeContentLength(resp.ContentLength)
+
+		if resp.ContentLength >= 0 {
+			total += offset
+		}
+
+		w = &progressWriter{w: file, downloaded: offset, total: total, fn: b.progress}
+	}
+
+	_, err = io.Copy(w, resp.Body)
+
+	if err != nil {
+		return &retryableError{err}
+	}
+
+	return nil
+}
+
+//normalizeContentLength maps http.Response.ContentLength's "unknown" sentinel
+//(-1) to 0, matching ProgressFunc's documented contract.
+func normalizeContentLength(n int64) int64 {
+	if n < 0 {
+		return 0
+	}
+
+	return n
+}
+
+//progressWriter tees writes through to an underlying writer while reporting
+//running totals through fn.
+type progressWriter struct {
+	w          io.Writer
+	downloaded int64
+	total      int64
+	fn         ProgressFunc
+}
+
+func (p *progressWriter) Write(buf []byte) (int, error) {
+	n, err := p.w.Write(buf)
+	p.downloaded += int64(n)
+	p.fn(p.downloaded, p.total)
+	return n, err
+}
@@ -0,0 +1,142 @@
+package binwrapper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloadAttemptResumesFromOffset(t *testing.T) {
+	full := "hello world"
+	already := full[:5]
+	rest := full[5:]
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rng := r.Header.Get("Range")
+
+		if rng != "bytes=5-" {
+			t.Errorf("Range header = %q, want %q", rng, "bytes=5-")
+		}
+
+		w.Header().Set("Content-Length", "6")
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(rest))
+	}))
+
+	defer server.Close()
+
+	dir := t.TempDir()
+	partName := filepath.Join(dir, "download.part")
+
+	if err := os.WriteFile(partName, []byte(already), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBinWrapper()
+
+	if err := b.downloadAttempt(server.URL, partName); err != nil {
+		t.Fatalf("downloadAttempt() = %v, want nil", err)
+	}
+
+	got, err := os.ReadFile(partName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != full {
+		t.Fatalf("partName content = %q, want %q", got, full)
+	}
+}
+
+func TestDownloadAttemptRestartsOnNonPartialResponse(t *testing.T) {
+	full := "hello world"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprint(len(full)))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(full))
+	}))
+
+	defer server.Close()
+
+	dir := t.TempDir()
+	partName := filepath.Join(dir, "download.part")
+
+	if err := os.WriteFile(partName, []byte("stale partial data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBinWrapper()
+
+	if err := b.downloadAttempt(server.URL, partName); err != nil {
+		t.Fatalf("downloadAttempt() = %v, want nil", err)
+	}
+
+	got, err := os.ReadFile(partName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != full {
+		t.Fatalf("partName content = %q, want %q (stale partial should be truncated, not appended to)", got, full)
+	}
+}
+
+func TestDownloadAttemptRetriesOn5xx(t *testing.T) {
+	full := "hello world"
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(full))
+	}))
+
+	defer server.Close()
+
+	dir := t.TempDir()
+	fileName, err := NewBinWrapper().Dest(dir).downloadFile(server.URL+"/tool.bin", NewSrc())
+
+	if err != nil {
+		t.Fatalf("downloadFile() = %v, want nil", err)
+	}
+
+	got, err := os.ReadFile(fileName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != full {
+		t.Fatalf("downloaded content = %q, want %q", got, full)
+	}
+
+	if attempts != 2 {
+		t.Fatalf("server saw %d attempts, want 2 (one failure, one retry)", attempts)
+	}
+}
+
+func TestNormalizeContentLength(t *testing.T) {
+	cases := map[int64]int64{
+		-1:     0,
+		0:      0,
+		1024:   1024,
+		-12345: 0,
+	}
+
+	for in, want := range cases {
+		if got := normalizeContentLength(in); got != want {
+			t.Errorf("normalizeContentLength(%d) = %d, want %d", in, got, want)
+		}
+	}
+}
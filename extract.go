@@ -0,0 +1,327 @@
+package binwrapper
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ulikunitz/xz"
+)
+
+//Extractor unpacks a downloaded archive. Match sniffs filename and the first
+//512 bytes of the file to decide whether this Extractor applies; Extract
+//unpacks src into dest, discarding the first strip leading path components
+//of each entry.
+type Extractor interface {
+	Match(filename string, header []byte) bool
+	Extract(src, dest string, strip int) error
+}
+
+var extractors = []Extractor{
+	tarExtractor{},
+	zipExtractor{},
+	gzipExtractor{},
+	bzip2Extractor{},
+	xzExtractor{},
+}
+
+//RegisterExtractor adds a custom Extractor, consulted before the built-ins.
+//Use it to plug in formats this package doesn't know about, e.g. a Windows
+//MSI or an Apple pkg.
+func RegisterExtractor(e Extractor) {
+	extractors = append([]Extractor{e}, extractors...)
+}
+
+//stripName discards the first strip leading path components of name,
+//reporting false if nothing is left (the entry was the component being
+//stripped).
+func stripName(name string, strip int) (string, bool) {
+	parts := strings.Split(filepath.ToSlash(name), "/")
+
+	if strip >= len(parts) {
+		return "", false
+	}
+
+	return filepath.Join(parts[strip:]...), true
+}
+
+//safeJoin joins dest and name, rejecting entries (e.g. "../../etc/passwd")
+//that would resolve outside dest — the classic tar-slip/zip-slip escape.
+func safeJoin(dest, name string) (string, error) {
+	target := filepath.Join(dest, name)
+	cleanDest := filepath.Clean(dest)
+
+	if target != cleanDest && !strings.HasPrefix(target, cleanDest+string(os.PathSeparator)) {
+		return "", fmt.Errorf("archive entry %q escapes destination %q", name, dest)
+	}
+
+	return target, nil
+}
+
+func extractTar(tr *tar.Reader, dest string, strip int) error {
+	for {
+		header, err := tr.Next()
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		name, ok := stripName(header.Name, strip)
+
+		if !ok || name == "" {
+			continue
+		}
+
+		target, err := safeJoin(dest, name)
+
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(target, tr, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeFile(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(target, os.O_RDWR|os.O_CREATE|os.O_TRUNC, mode)
+
+	if err != nil {
+		return err
+	}
+
+	defer out.Close()
+
+	_, err = io.Copy(out, r)
+
+	return err
+}
+
+type tarExtractor struct{}
+
+func (tarExtractor) Match(filename string, header []byte) bool {
+	if len(header) >= 262 && string(header[257:262]) == "ustar" {
+		return true
+	}
+
+	//Pre-POSIX V7 archives (still readable by archive/tar) carry no magic at
+	//all; fall back to validating the header checksum field instead.
+	return validTarChecksum(header)
+}
+
+//validTarChecksum reports whether block looks like a tar header block by
+//recomputing its checksum field (offset 148, 8 bytes) the way archive/tar
+//itself does, accepting either the unsigned or signed-byte sum some writers
+//produce.
+func validTarChecksum(block []byte) bool {
+	if len(block) < 512 {
+		return false
+	}
+
+	stored, err := strconv.ParseUint(strings.TrimRight(strings.TrimSpace(string(block[148:156])), "\x00"), 8, 64)
+
+	if err != nil {
+		return false
+	}
+
+	var unsigned, signed int64
+
+	for i, c := range block {
+		if i >= 148 && i < 156 {
+			c = ' '
+		}
+
+		unsigned += int64(c)
+		signed += int64(int8(c))
+	}
+
+	return int64(stored) == unsigned || int64(stored) == signed
+}
+
+func (tarExtractor) Extract(src, dest string, strip int) error {
+	f, err := os.Open(src)
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	return extractTar(tar.NewReader(f), dest, strip)
+}
+
+type zipExtractor struct{}
+
+func (zipExtractor) Match(filename string, header []byte) bool {
+	return len(header) >= 4 && header[0] == 'P' && header[1] == 'K' &&
+		(header[2] == 3 || header[2] == 5 || header[2] == 7)
+}
+
+func (zipExtractor) Extract(src, dest string, strip int) error {
+	r, err := zip.OpenReader(src)
+
+	if err != nil {
+		return err
+	}
+
+	defer r.Close()
+
+	for _, f := range r.File {
+		name, ok := stripName(f.Name, strip)
+
+		if !ok || name == "" {
+			continue
+		}
+
+		target, err := safeJoin(dest, name)
+
+		if err != nil {
+			return err
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, f.Mode()); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		rc, err := f.Open()
+
+		if err != nil {
+			return err
+		}
+
+		err = writeFile(target, rc, f.Mode())
+		rc.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//extractCompressedStream unpacks a single-stream compression format (gzip,
+//bzip2, xz). If the decompressed stream is itself a tar (the common
+//".tar.gz"/".tar.bz2"/".tar.xz" case) its entries are extracted individually;
+//otherwise the whole stream is written out as one file, named after src with
+//its compression suffix stripped, matching raw single-file payloads like a
+//plain ".gz" download.
+func extractCompressedStream(r io.Reader, dest, src string, strip int) error {
+	br := bufio.NewReaderSize(r, 512)
+
+	peek, _ := br.Peek(262)
+
+	if len(peek) >= 262 && string(peek[257:262]) == "ustar" {
+		return extractTar(tar.NewReader(br), dest, strip)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(src), filepath.Ext(src))
+
+	if name == "" {
+		name = filepath.Base(src)
+	}
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+
+	return writeFile(filepath.Join(dest, name), br, 0755)
+}
+
+type gzipExtractor struct{}
+
+func (gzipExtractor) Match(filename string, header []byte) bool {
+	return len(header) >= 2 && header[0] == 0x1f && header[1] == 0x8b
+}
+
+func (gzipExtractor) Extract(src, dest string, strip int) error {
+	f, err := os.Open(src)
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+
+	if err != nil {
+		return err
+	}
+
+	defer gz.Close()
+
+	return extractCompressedStream(gz, dest, src, strip)
+}
+
+type bzip2Extractor struct{}
+
+func (bzip2Extractor) Match(filename string, header []byte) bool {
+	return len(header) >= 3 && header[0] == 'B' && header[1] == 'Z' && header[2] == 'h'
+}
+
+func (bzip2Extractor) Extract(src, dest string, strip int) error {
+	f, err := os.Open(src)
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	return extractCompressedStream(bzip2.NewReader(f), dest, src, strip)
+}
+
+type xzExtractor struct{}
+
+var xzMagic = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+
+func (xzExtractor) Match(filename string, header []byte) bool {
+	return len(header) >= len(xzMagic) && string(header[:len(xzMagic)]) == string(xzMagic)
+}
+
+func (xzExtractor) Extract(src, dest string, strip int) error {
+	f, err := os.Open(src)
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	xr, err := xz.NewReader(f)
+
+	if err != nil {
+		return err
+	}
+
+	return extractCompressedStream(xr, dest, src, strip)
+}
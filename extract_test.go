@@ -0,0 +1,308 @@
+package binwrapper
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/ulikunitz/xz"
+)
+
+func TestTarExtractorRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "archive.tar")
+
+	f, err := os.Create(archivePath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tw := tar.NewWriter(f)
+
+	entries := map[string]string{
+		"top/file.txt":   "hello",
+		"top/sub/nested": "world",
+	}
+
+	for name, content := range entries {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Close()
+
+	dest := t.TempDir()
+
+	if err := (tarExtractor{}).Extract(archivePath, dest, 1); err != nil {
+		t.Fatalf("Extract() = %v, want nil", err)
+	}
+
+	assertFileContent(t, filepath.Join(dest, "file.txt"), "hello")
+	assertFileContent(t, filepath.Join(dest, "sub", "nested"), "world")
+}
+
+func TestZipExtractorRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "archive.zip")
+
+	f, err := os.Create(archivePath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zw := zip.NewWriter(f)
+
+	entries := map[string]string{
+		"top/file.txt":   "hello",
+		"top/sub/nested": "world",
+	}
+
+	for name, content := range entries {
+		w, err := zw.Create(name)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Close()
+
+	dest := t.TempDir()
+
+	if err := (zipExtractor{}).Extract(archivePath, dest, 1); err != nil {
+		t.Fatalf("Extract() = %v, want nil", err)
+	}
+
+	assertFileContent(t, filepath.Join(dest, "file.txt"), "hello")
+	assertFileContent(t, filepath.Join(dest, "sub", "nested"), "world")
+}
+
+func TestGzipExtractorRoundTripPlainFile(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "tool.gz")
+
+	f, err := os.Create(archivePath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gw := gzip.NewWriter(f)
+
+	if _, err := gw.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Close()
+
+	dest := t.TempDir()
+
+	if err := (gzipExtractor{}).Extract(archivePath, dest, 0); err != nil {
+		t.Fatalf("Extract() = %v, want nil", err)
+	}
+
+	assertFileContent(t, filepath.Join(dest, "tool"), "hello")
+}
+
+func TestGzipExtractorRoundTripWrappedTar(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "tool.tar.gz")
+
+	f, err := os.Create(archivePath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	content := "hello"
+
+	if err := tw.WriteHeader(&tar.Header{Name: "top/file.txt", Mode: 0644, Size: int64(len(content))}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tw.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Close()
+
+	dest := t.TempDir()
+
+	if err := (gzipExtractor{}).Extract(archivePath, dest, 1); err != nil {
+		t.Fatalf("Extract() = %v, want nil", err)
+	}
+
+	assertFileContent(t, filepath.Join(dest, "file.txt"), "hello")
+}
+
+func TestXzExtractorRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "tool.xz")
+
+	f, err := os.Create(archivePath)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	xw, err := xz.NewWriter(f)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := xw.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := xw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Close()
+
+	dest := t.TempDir()
+
+	if err := (xzExtractor{}).Extract(archivePath, dest, 0); err != nil {
+		t.Fatalf("Extract() = %v, want nil", err)
+	}
+
+	assertFileContent(t, filepath.Join(dest, "tool"), "hello")
+}
+
+func TestBzip2ExtractorRoundTrip(t *testing.T) {
+	bzip2Bin, err := exec.LookPath("bzip2")
+
+	if err != nil {
+		t.Skip("bzip2 binary not available to build a fixture")
+	}
+
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "tool")
+
+	if err := os.WriteFile(srcPath, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := exec.Command(bzip2Bin, srcPath).Run(); err != nil {
+		t.Fatalf("building bzip2 fixture: %v", err)
+	}
+
+	dest := t.TempDir()
+
+	if err := (bzip2Extractor{}).Extract(srcPath+".bz2", dest, 0); err != nil {
+		t.Fatalf("Extract() = %v, want nil", err)
+	}
+
+	assertFileContent(t, filepath.Join(dest, "tool"), "hello")
+}
+
+func assertFileContent(t *testing.T, path, want string) {
+	t.Helper()
+
+	got, err := os.ReadFile(path)
+
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+
+	if string(got) != want {
+		t.Errorf("%s content = %q, want %q", path, got, want)
+	}
+}
+
+func TestSafeJoinRejectsEscapingEntries(t *testing.T) {
+	dest := filepath.FromSlash("/tmp/some/dest")
+
+	cases := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"file.txt", false},
+		{"nested/file.txt", false},
+		{"../pwned.txt", true},
+		{"../../etc/passwd", true},
+		{"a/../../pwned.txt", true},
+	}
+
+	for _, c := range cases {
+		_, err := safeJoin(dest, c.name)
+
+		if c.wantErr && err == nil {
+			t.Errorf("safeJoin(%q, %q): want error, got nil", dest, c.name)
+		}
+
+		if !c.wantErr && err != nil {
+			t.Errorf("safeJoin(%q, %q): unexpected error %v", dest, c.name, err)
+		}
+	}
+}
+
+func TestValidTarChecksumAcceptsV7Header(t *testing.T) {
+	block := make([]byte, 512)
+	copy(block, "file.txt")
+	copy(block[100:], "0000644\x00")     // mode
+	copy(block[108:], "0000000\x00")     // uid
+	copy(block[116:], "0000000\x00")     // gid
+	copy(block[124:], "00000000000\x00") // size
+	copy(block[136:], "00000000000\x00") // mtime
+
+	for i := 148; i < 156; i++ {
+		block[i] = ' '
+	}
+
+	var sum int64
+
+	for _, c := range block {
+		sum += int64(c)
+	}
+
+	copy(block[148:], strconv.FormatInt(sum, 8)+"\x00")
+
+	if !validTarChecksum(block) {
+		t.Fatal("validTarChecksum() = false for a well-formed V7 header block")
+	}
+
+	if !(tarExtractor{}).Match("archive.tar", block) {
+		t.Fatal("tarExtractor.Match() = false for a well-formed V7 header with no ustar magic")
+	}
+}
@@ -0,0 +1,26 @@
+package binwrapper
+
+import "runtime"
+
+//osFilterObj returns the first src whose Os/Arch constraints match the
+//current runtime.GOOS/GOARCH, treating an empty constraint as "any". It
+//returns nil if src is empty or none match.
+func osFilterObj(src []*Src) *Src {
+	for _, s := range src {
+		if s == nil {
+			continue
+		}
+
+		if s.os != "" && s.os != runtime.GOOS {
+			continue
+		}
+
+		if s.arch != "" && s.arch != runtime.GOARCH {
+			continue
+		}
+
+		return s
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package binwrapper
+
+import "strings"
+
+var defaultOsAliases = map[string]string{
+	"darwin": "macos",
+}
+
+var defaultArchAliases = map[string]string{
+	"amd64": "x86_64",
+	"386":   "i386",
+	"arm64": "aarch64",
+}
+
+//defaultPlatforms is the OS/arch matrix BinWrapper.Platform registers a
+//source for.
+var defaultPlatforms = [][2]string{
+	{"linux", "amd64"},
+	{"linux", "386"},
+	{"linux", "arm64"},
+	{"darwin", "amd64"},
+	{"darwin", "arm64"},
+	{"windows", "amd64"},
+	{"windows", "386"},
+}
+
+//ArchAlias overrides how goArch is rendered when Platform expands the
+//{arch} token, for projects that don't follow the common
+//x86_64/i386/aarch64 naming convention.
+func (s *Src) ArchAlias(goArch, remoteArch string) *Src {
+	if s.archAliases == nil {
+		s.archAliases = map[string]string{}
+	}
+
+	s.archAliases[goArch] = remoteArch
+	return s
+}
+
+//Platform expands a templated URL, e.g.
+//"https://github.com/{owner}/{repo}/releases/download/{version}/{name}-{version}-{os}-{arch}.{ext}",
+//by substituting {os} and {arch} with this Src's Os/Arch translated into the
+//naming convention most release assets use (darwin->macos, amd64->x86_64,
+//386->i386, arm64->aarch64). Any other placeholder, including {version},
+//must already be resolved by the caller (e.g. via strings.Replace on pattern
+//before calling Platform) - Src.version is used to pick a source during
+//resolution, not to expand this template. Use ArchAlias to override the
+//default mapping.
+func (s *Src) Platform(pattern string) *Src {
+	s.url = strings.NewReplacer(
+		"{os}", s.platformOs(),
+		"{arch}", s.platformArch(),
+	).Replace(pattern)
+
+	return s
+}
+
+func (s *Src) platformOs() string {
+	if alias, ok := defaultOsAliases[s.os]; ok {
+		return alias
+	}
+
+	return s.os
+}
+
+func (s *Src) platformArch() string {
+	if alias, ok := s.archAliases[s.arch]; ok {
+		return alias
+	}
+
+	if alias, ok := defaultArchAliases[s.arch]; ok {
+		return alias
+	}
+
+	return s.arch
+}
+
+//Platform registers one source per entry in the default OS/arch matrix
+//(linux/darwin/windows across amd64/386/arm64, as applicable), expanding
+//pattern for each via Src.Platform. It eliminates the need to hand-register
+//a Src per OS/arch pair for projects that publish one archive per platform,
+//mirroring how tools like go-getter and krew resolve cross-platform assets.
+func (b *BinWrapper) Platform(pattern string) *BinWrapper {
+	for _, combo := range defaultPlatforms {
+		b.Src(NewSrc().Os(combo[0]).Arch(combo[1]).Platform(pattern))
+	}
+
+	return b
+}
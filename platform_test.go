@@ -0,0 +1,56 @@
+package binwrapper
+
+import "testing"
+
+func TestPlatformExpandsOsAndArch(t *testing.T) {
+	cases := []struct {
+		os, arch string
+		want     string
+	}{
+		{"linux", "amd64", "tool-linux-x86_64.tar.gz"},
+		{"darwin", "arm64", "tool-macos-aarch64.tar.gz"},
+		{"windows", "386", "tool-windows-i386.tar.gz"},
+	}
+
+	for _, c := range cases {
+		src := NewSrc().Os(c.os).Arch(c.arch).Platform("tool-{os}-{arch}.tar.gz")
+
+		if src.url != c.want {
+			t.Errorf("Os(%q).Arch(%q).Platform(...) url = %q, want %q", c.os, c.arch, src.url, c.want)
+		}
+	}
+}
+
+func TestPlatformLeavesUnknownPlaceholdersUntouched(t *testing.T) {
+	src := NewSrc().Os("linux").Arch("amd64").Version("1.2.3").Platform("tool-{version}-{os}-{arch}.tar.gz")
+
+	want := "tool-{version}-linux-x86_64.tar.gz"
+
+	if src.url != want {
+		t.Errorf("Platform(...) url = %q, want %q (the {version} token is out of scope)", src.url, want)
+	}
+}
+
+func TestArchAliasOverridesDefault(t *testing.T) {
+	src := NewSrc().Os("linux").Arch("amd64").ArchAlias("amd64", "x64").Platform("tool-{arch}.tar.gz")
+
+	if src.url != "tool-x64.tar.gz" {
+		t.Errorf("url = %q, want %q", src.url, "tool-x64.tar.gz")
+	}
+}
+
+func TestBinWrapperPlatformRegistersDefaultMatrix(t *testing.T) {
+	b := NewBinWrapper().Platform("tool-{os}-{arch}.tar.gz")
+
+	if len(b.src) != len(defaultPlatforms) {
+		t.Fatalf("len(b.src) = %d, want %d", len(b.src), len(defaultPlatforms))
+	}
+
+	for i, combo := range defaultPlatforms {
+		s := b.src[i]
+
+		if s.os != combo[0] || s.arch != combo[1] {
+			t.Errorf("src[%d] = {%q, %q}, want {%q, %q}", i, s.os, s.arch, combo[0], combo[1])
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package binwrapper
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"time"
+)
+
+//SetStdout tees the binary's standard output to w in addition to capturing
+//it in StdOut.
+func (b *BinWrapper) SetStdout(w io.Writer) *BinWrapper {
+	b.stdout = w
+	return b
+}
+
+//SetStderr tees the binary's standard error to w in addition to capturing
+//it in StdErr.
+func (b *BinWrapper) SetStderr(w io.Writer) *BinWrapper {
+	b.stderr = w
+	return b
+}
+
+//SetStdin connects r to the binary's standard input.
+func (b *BinWrapper) SetStdin(r io.Reader) *BinWrapper {
+	b.stdin = r
+	return b
+}
+
+//Timeout cancels the binary if it hasn't exited after d. Equivalent to
+//deriving a context with context.WithTimeout and calling RunContext.
+func (b *BinWrapper) Timeout(d time.Duration) *BinWrapper {
+	b.timeout = d
+	return b
+}
+
+//ExitCode returns the process's exit code from the last Run/RunContext call,
+//or -1 if it hasn't run yet or didn't exit normally.
+func (b *BinWrapper) ExitCode() int {
+	return b.exitCode
+}
+
+//RunContext runs the binary with the provided arg list, appended to args set
+//through Arg. Stdout/stderr are always captured into StdOut/StdErr, and are
+//also teed into the writers set with SetStdout/SetStderr, if any. If ctx is
+//canceled (or Timeout elapses first) the process is killed and ctx.Err() is
+//returned.
+func (b *BinWrapper) RunContext(ctx context.Context, arg ...string) error {
+	if b.src != nil && len(b.src) > 0 {
+		err := b.findExisting()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if b.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+
+	arg = append(b.Args, arg...)
+
+	cmd := exec.CommandContext(ctx, b.Path(), arg...)
+	cmd.Stdin = b.stdin
+
+	var stdout, stderr bytes.Buffer
+
+	if b.stdout != nil {
+		cmd.Stdout = io.MultiWriter(&stdout, b.stdout)
+	} else {
+		cmd.Stdout = &stdout
+	}
+
+	if b.stderr != nil {
+		cmd.Stderr = io.MultiWriter(&stderr, b.stderr)
+	} else {
+		cmd.Stderr = &stderr
+	}
+
+	err := cmd.Run()
+
+	b.StdOut = stdout.Bytes()
+	b.StdErr = stderr.Bytes()
+	b.exitCode = exitCodeOf(err)
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
+}
+
+func exitCodeOf(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode()
+	}
+
+	return -1
+}
@@ -0,0 +1,23 @@
+package binwrapper
+
+import "testing"
+
+func TestExitCodeBeforeRunIsMinusOne(t *testing.T) {
+	if code := NewBinWrapper().ExitCode(); code != -1 {
+		t.Fatalf("ExitCode() before any Run = %d, want -1", code)
+	}
+}
+
+func TestExitCodeAfterRun(t *testing.T) {
+	b := NewBinWrapper().ExecPath("/bin/sh")
+
+	err := b.Run("-c", "exit 3")
+
+	if err == nil {
+		t.Fatal("Run() with a non-zero exit should return an error")
+	}
+
+	if b.ExitCode() != 3 {
+		t.Fatalf("ExitCode() = %d, want 3", b.ExitCode())
+	}
+}
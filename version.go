@@ -0,0 +1,210 @@
+package binwrapper
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+//Version ties the source to the concrete version it contains (e.g. "1.2.3").
+//BinWrapper.Version constraints are matched against this value when picking
+//a source to download.
+func (s *Src) Version(value string) *Src {
+	s.version = value
+	return s
+}
+
+//Version declares a semver constraint (e.g. ">=1.2.0 <2") that the resolved
+//binary must satisfy. BinWrapper.Path then resolves to
+//<dest>/<binary>-<version>/<execPath>, and findExisting prefers an already
+//installed version over downloading a new one.
+func (b *BinWrapper) Version(constraint string) *BinWrapper {
+	b.versionConstraint = constraint
+	return b
+}
+
+func (b *BinWrapper) binaryName() string {
+	return filepath.Base(b.execPath)
+}
+
+func (b *BinWrapper) versionDir(version string) string {
+	return fmt.Sprintf("%s-%s", b.binaryName(), version)
+}
+
+//constraintOperator matches a bare comparison operator token, produced when
+//a constraint like ">=1.2.0 <2" is split on whitespace.
+var constraintOperator = regexp.MustCompile(`^(=|!=|>=|<=|>|<|~|\^)$`)
+
+//normalizeConstraint rewrites the space-separated AND syntax shown in this
+//package's docs (">=1.2.0 <2") into the comma-separated form
+//github.com/Masterminds/semver actually requires, so callers don't have to
+//know that detail. Strings that already contain a comma or an OR ("||") are
+//left untouched.
+func normalizeConstraint(s string) string {
+	if strings.Contains(s, ",") || strings.Contains(s, "||") {
+		return s
+	}
+
+	fields := strings.Fields(s)
+
+	var clauses []string
+	pending := ""
+
+	for _, tok := range fields {
+		if constraintOperator.MatchString(tok) {
+			pending = tok
+			continue
+		}
+
+		if pending != "" {
+			clauses = append(clauses, pending+tok)
+			pending = ""
+		} else {
+			clauses = append(clauses, tok)
+		}
+	}
+
+	if pending != "" {
+		clauses = append(clauses, pending)
+	}
+
+	return strings.Join(clauses, ", ")
+}
+
+//matchingSrc narrows osFilterObj's OS/arch match down to sources whose
+//Version satisfies the declared constraint, if any.
+func (b *BinWrapper) matchingSrc() *Src {
+	if b.versionConstraint == "" {
+		return osFilterObj(b.src)
+	}
+
+	constraint, err := semver.NewConstraint(normalizeConstraint(b.versionConstraint))
+
+	if err != nil {
+		return osFilterObj(b.src)
+	}
+
+	var candidates []*Src
+
+	for _, s := range b.src {
+		if s.version == "" {
+			continue
+		}
+
+		v, err := semver.NewVersion(s.version)
+
+		if err != nil || !constraint.Check(v) {
+			continue
+		}
+
+		candidates = append(candidates, s)
+	}
+
+	return osFilterObj(candidates)
+}
+
+//InstalledVersions returns the versions of this binary cached under Dest,
+//newest first.
+func (b *BinWrapper) InstalledVersions() ([]string, error) {
+	entries, err := ioutil.ReadDir(b.dest)
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := b.binaryName() + "-"
+
+	var versions []*semver.Version
+
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasPrefix(e.Name(), prefix) {
+			continue
+		}
+
+		v, err := semver.NewVersion(strings.TrimPrefix(e.Name(), prefix))
+
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, v)
+	}
+
+	sort.Sort(sort.Reverse(semver.Collection(versions)))
+
+	result := make([]string, len(versions))
+
+	for i, v := range versions {
+		result[i] = v.String()
+	}
+
+	return result, nil
+}
+
+//bestInstalledVersion returns the newest installed version satisfying
+//versionConstraint, or "" if none is installed or none match.
+func (b *BinWrapper) bestInstalledVersion() (string, error) {
+	constraint, err := semver.NewConstraint(normalizeConstraint(b.versionConstraint))
+
+	if err != nil {
+		return "", fmt.Errorf("parsing version constraint %q: %v", b.versionConstraint, err)
+	}
+
+	versions, err := b.InstalledVersions()
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, v := range versions {
+		sv, err := semver.NewVersion(v)
+
+		if err != nil {
+			continue
+		}
+
+		if constraint.Check(sv) {
+			return v, nil
+		}
+	}
+
+	return "", nil
+}
+
+//Prune deletes cached installs beyond the keep newest versions, so long
+//running services don't leak disk as new versions are downloaded.
+func (b *BinWrapper) Prune(keep int) error {
+	if keep < 0 {
+		keep = 0
+	}
+
+	versions, err := b.InstalledVersions()
+
+	if err != nil {
+		return err
+	}
+
+	if keep >= len(versions) {
+		return nil
+	}
+
+	for _, v := range versions[keep:] {
+		dir := filepath.Join(b.dest, b.versionDir(v))
+
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package binwrapper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/semver"
+)
+
+func TestNormalizeConstraintAcceptsSpaceSeparatedAnd(t *testing.T) {
+	cases := []string{
+		">=1.0.0 <2",
+		">= 1.0.0 < 2.0.0",
+		"1.2.3",
+		">=1.0.0,<2",
+	}
+
+	for _, c := range cases {
+		if _, err := semver.NewConstraint(normalizeConstraint(c)); err != nil {
+			t.Errorf("normalizeConstraint(%q) -> %q did not parse: %v", c, normalizeConstraint(c), err)
+		}
+	}
+}
+
+//A version constraint should resolve against a cache populated by a prior
+//run before execPath has ever been set via Path()/ExecPath() directly,
+//since callers declare it on the Src like every other selector.
+func TestFindExistingUsesCachedVersionWithoutPriorExecPath(t *testing.T) {
+	dest, err := os.MkdirTemp("", "go-binwrapper-version-test")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dest)
+
+	cached := filepath.Join(dest, "mybinary-1.2.3")
+
+	if err := os.MkdirAll(cached, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(cached, "mybinary"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBinWrapper().
+		Dest(dest).
+		Version(">=1.0.0 <2").
+		Src(NewSrc().ExecPath("mybinary").Version("1.2.3"))
+
+	if err := b.findExisting(); err != nil {
+		t.Fatalf("findExisting() returned %v, want nil (cache should satisfy the constraint)", err)
+	}
+
+	if b.resolvedVersion != "1.2.3" {
+		t.Fatalf("resolvedVersion = %q, want %q", b.resolvedVersion, "1.2.3")
+	}
+}